@@ -0,0 +1,31 @@
+package models
+
+import "errors"
+
+// Channel identifies which transport a Template (and, ultimately, a
+// Campaign) delivers through. Most of Gophish's existing model types
+// (Template, HeaderProfile, SMTP) are email-specific; Channel lets a
+// Campaign mix email and SMS targets without duplicating the
+// recipient/result tracking machinery.
+type Channel string
+
+const (
+	// ChannelEmail is the original, and default, delivery channel.
+	ChannelEmail Channel = "email"
+	// ChannelSMS delivers via an SMSProfile (Twilio/Vonage/SMPP).
+	ChannelSMS Channel = "sms"
+)
+
+// ErrInvalidChannel is thrown when a Channel value other than the known
+// constants is supplied.
+var ErrInvalidChannel = errors.New("Invalid delivery channel specified")
+
+// Validate checks that c is one of the known Channel constants.
+func (c Channel) Validate() error {
+	switch c {
+	case ChannelEmail, ChannelSMS:
+		return nil
+	default:
+		return ErrInvalidChannel
+	}
+}
@@ -0,0 +1,120 @@
+package headers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gophish/gomail"
+)
+
+// ProfileConfig is the JSON shape of a user-defined profile entry in
+// config.json's "header_profiles" object, keyed by profile name. It lets
+// an operator add a new MUA fingerprint without recompiling Gophish.
+type ProfileConfig struct {
+	// Name is the human readable name shown in the admin UI.
+	Name string `json:"name"`
+	// Headers are static header values applied verbatim, in addition to
+	// the generated Message-Id, Date and Content-Type headers below.
+	Headers map[string]string `json:"headers"`
+	// Order lists every header name (including "Message-Id" and "Date")
+	// in the exact order they should appear on the wire. Headers omitted
+	// from Order are never sent. Content-Type is never settable here:
+	// gomail.Message.WriteTo always computes its own Content-Type and
+	// MIME boundary once a message has more than one part or an
+	// attachment, so a profile-supplied one would only produce a
+	// duplicate, conflicting header.
+	Order []string `json:"order"`
+	// MessageIDTemplate controls the generated Message-Id. It supports
+	// the placeholders {{rand}} (22 random hex chars), {{host}} (the
+	// From address's domain) and {{hash}} (a per-recipient hash of the
+	// target's email, stable across sends so previews don't change).
+	MessageIDTemplate string `json:"message_id_template"`
+	// DateLayout is a Go time layout string (see the time package) used
+	// to format the Date header. Defaults to time.RFC1123Z.
+	DateLayout string `json:"date_layout"`
+}
+
+// configProfiles is the top-level shape of the "header_profiles" section
+// of config.json: a map of profile name to its ProfileConfig.
+type configProfiles map[string]ProfileConfig
+
+// LoadFromFile reads profile definitions from the "header_profiles"
+// object of the config.json at path and registers each one, so that
+// operators can add new MUA fingerprints without recompiling Gophish.
+// Profiles with the same name as a built-in profile override it.
+func LoadFromFile(path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading header profile config %s: %w", path, err)
+	}
+
+	var wrapper struct {
+		HeaderProfiles configProfiles `json:"header_profiles"`
+	}
+	if err := json.Unmarshal(raw, &wrapper); err != nil {
+		return fmt.Errorf("error parsing header profile config %s: %w", path, err)
+	}
+
+	for name, cfg := range wrapper.HeaderProfiles {
+		Register(name, configProfile{name: name, cfg: cfg})
+	}
+	return nil
+}
+
+// configProfile is a Profile backed by a user-supplied ProfileConfig.
+type configProfile struct {
+	name string
+	cfg  ProfileConfig
+}
+
+func (p configProfile) Name() string {
+	if p.cfg.Name != "" {
+		return p.cfg.Name
+	}
+	return p.name
+}
+
+func (p configProfile) Apply(msg *gomail.Message, params RecipientParams, attachments bool) error {
+	host := mailHost(params.From)
+
+	layout := p.cfg.DateLayout
+	if layout == "" {
+		layout = time.RFC1123Z
+	}
+
+	headers := make(map[string]string, len(p.cfg.Headers)+2)
+	for k, v := range p.cfg.Headers {
+		headers[k] = v
+	}
+	headers["Date"] = time.Now().Format(layout)
+	headers["Message-Id"] = p.messageID(host, params.Email)
+
+	order := p.cfg.Order
+	if len(order) == 0 {
+		// No explicit order was configured; fall back to whatever order
+		// the map iterates in plus the generated headers last.
+		for k := range headers {
+			order = append(order, k)
+		}
+	}
+	setOrdered(msg, order, headers)
+	return nil
+}
+
+// messageID expands MessageIDTemplate's placeholders into a concrete
+// Message-Id value.
+func (p configProfile) messageID(host, email string) string {
+	tmpl := p.cfg.MessageIDTemplate
+	if tmpl == "" {
+		return defaultMessageID(host)
+	}
+	r := strings.NewReplacer(
+		"{{rand}}", randomHex(22),
+		"{{host}}", host,
+		"{{hash}}", recipientHash(email),
+	)
+	return r.Replace(tmpl)
+}
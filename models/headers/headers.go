@@ -0,0 +1,123 @@
+// Package headers implements Gophish's pluggable MUA header-profile
+// subsystem. A Profile knows how to stamp a set of client-accurate
+// headers onto an outgoing message -- Message-ID format, Date layout,
+// and header ordering -- so that a campaign sent with, say, the
+// "outlook" profile produces an envelope that is difficult to
+// distinguish from one sent by real Outlook. Profiles never set
+// Content-Type or a MIME boundary themselves: gomail.Message.WriteTo
+// always computes and writes its own once a message has more than one
+// part or an attachment, so a profile-supplied one would only collide
+// with it.
+//
+// Profiles are looked up by the same name stored in Template.HeaderProfile.
+// Built-in profiles are registered in profiles.go; additional profiles can
+// be loaded at runtime from config.json via LoadFromFile.
+package headers
+
+import (
+	"sync"
+
+	"github.com/gophish/gomail"
+)
+
+// RecipientParams is the subset of a send's recipient/campaign context a
+// Profile needs in order to stamp per-recipient headers. It deliberately
+// doesn't reference models.PhishingTemplateContext: this package sits
+// under models/ but must stay a leaf the models package itself can
+// import (e.g. from Template.Validate), so it can never depend on
+// models without an import cycle. Callers that already have a
+// models.PhishingTemplateContext build a RecipientParams from it.
+type RecipientParams struct {
+	// From is the envelope From address, used to derive the host half
+	// of generated Message-IDs.
+	From string
+	// Email is the recipient's address, used by profiles (like Gmail)
+	// whose Message-IDs embed a per-recipient hash.
+	Email string
+}
+
+// Profile generates the realistic, client-specific headers for a single
+// outgoing message. Implementations should be safe for concurrent use,
+// since the mailer sends to many recipients in parallel.
+type Profile interface {
+	// Name returns the human readable name of the profile, as shown in
+	// the admin UI.
+	Name() string
+
+	// Apply stamps msg with this profile's headers for the given
+	// recipient. attachments reports whether the template has one or
+	// more attachments, since several profiles change their MIME
+	// structure (flat multipart/alternative vs. nested
+	// multipart/mixed) when attachments are present.
+	Apply(msg *gomail.Message, params RecipientParams, attachments bool) error
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Profile{}
+)
+
+// Register adds a profile to the registry under name, overwriting any
+// existing profile with the same name. Built-in profiles register
+// themselves from an init() in profiles.go; profiles loaded from
+// config.json are registered by LoadFromFile.
+func Register(name string, p Profile) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = p
+}
+
+// Get returns the profile registered under name. If name is empty or
+// unknown, the "default" profile is returned instead, mirroring the
+// fallback behavior of the legacy GetHeadersForProfile.
+func Get(name string) Profile {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	p, ok := registry[name]
+	if !ok {
+		return registry["default"]
+	}
+	return p
+}
+
+// Registered reports whether name refers to a known profile, so that
+// Template validation can reject unknown profiles without the models
+// package needing to import this one.
+func Registered(name string) bool {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	_, ok := registry[name]
+	return ok
+}
+
+// Names returns the names of every registered profile, sorted by
+// registration order is not guaranteed; callers that need a stable order
+// should sort the result themselves.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	return names
+}
+
+// setOrdered writes headers to msg in exactly the order given by order,
+// skipping any header not present in headers. Many spam filters key off
+// header ordering, so profiles should always go through this helper
+// rather than ranging over a map.
+//
+// Profiles must never set "Content-Type" through this (or any other)
+// path: gomail.Message.WriteTo computes and writes its own Content-Type
+// and MIME boundary whenever a message has more than one part or an
+// attachment, ignoring any value set on the message beforehand. Setting
+// one manually doesn't just get overridden -- WriteTo writes out both,
+// producing a message with two conflicting Content-Type headers.
+func setOrdered(msg *gomail.Message, order []string, headers map[string]string) {
+	for _, name := range order {
+		if v, ok := headers[name]; ok {
+			msg.SetHeader(name, v)
+		}
+	}
+}
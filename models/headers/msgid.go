@@ -0,0 +1,131 @@
+package headers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"hash/fnv"
+	"math/big"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+const tokenCharset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// randomToken returns a cryptographically random alphanumeric string of
+// the given length, used for Message-IDs and MIME boundaries. Unlike the
+// legacy generateBoundary helper, this uses crypto/rand so that
+// Message-IDs can't be predicted across a campaign.
+func randomToken(n int) string {
+	b := make([]byte, n)
+	max := big.NewInt(int64(len(tokenCharset)))
+	for i := range b {
+		idx, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			// crypto/rand failing is effectively unrecoverable; fall
+			// back to a fixed character rather than panicking so a
+			// send never fails just because a Message-ID looks dull.
+			b[i] = tokenCharset[0]
+			continue
+		}
+		b[i] = tokenCharset[idx.Int64()]
+	}
+	return string(b)
+}
+
+// randomHex returns n random lowercase hex characters.
+func randomHex(n int) string {
+	const hexCharset = "0123456789abcdef"
+	b := make([]byte, n)
+	max := big.NewInt(int64(len(hexCharset)))
+	for i := range b {
+		idx, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			b[i] = hexCharset[0]
+			continue
+		}
+		b[i] = hexCharset[idx.Int64()]
+	}
+	return string(b)
+}
+
+// mailHost returns the domain portion of a From address, falling back to
+// "localhost" if it can't be parsed. It's used as the right-hand side of
+// generated Message-IDs.
+func mailHost(from string) string {
+	addr, err := mail.ParseAddress(from)
+	if err != nil {
+		return "localhost"
+	}
+	parts := strings.SplitN(addr.Address, "@", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return "localhost"
+	}
+	return parts[1]
+}
+
+// recipientHash returns a short, deterministic-per-recipient hash, used
+// by profiles (like Gmail) whose Message-IDs embed a stable-looking hash
+// rather than pure randomness.
+func recipientHash(email string) string {
+	h := fnv.New64a()
+	h.Write([]byte(email))
+	return fmt.Sprintf("%x", h.Sum64())
+}
+
+// outlookMessageID builds a Message-ID in the form Outlook's MAPI/SMTP
+// gateway emits: a bare GUID, uppercase, at the sending host.
+func outlookMessageID(host string) string {
+	guid := fmt.Sprintf("%s-%s-%s-%s-%s",
+		randomHex(8), randomHex(4), randomHex(4), randomHex(4), randomHex(12))
+	return fmt.Sprintf("<%s@%s>", strings.ToUpper(guid), host)
+}
+
+// appleMailMessageID builds a Message-ID in the form Apple Mail emits: a
+// long lowercase hex string at the local machine's hostname-like name.
+func appleMailMessageID(host string) string {
+	return fmt.Sprintf("<%s@%s>", randomHex(36), host)
+}
+
+// gmailMessageID builds a Message-ID in the form Gmail's web compose
+// emits: a "CA" prefix followed by a base64-ish token, a "+" separator,
+// and a hash derived from the recipient so previews stay stable for a
+// given target, ending at mail.gmail.com.
+func gmailMessageID(email string) string {
+	return fmt.Sprintf("<CA%s+%s@mail.gmail.com>", randomToken(27), recipientHash(email))
+}
+
+// defaultMessageID builds a generic, client-agnostic Message-ID of the
+// form Go's own net/smtp would produce.
+func defaultMessageID(host string) string {
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), randomToken(8), host)
+}
+
+// threadIndex fabricates an Outlook-style Thread-Index: a base64 encoded
+// blob made up of a 22-byte FILETIME header block followed by a 5-byte
+// child block. Real Outlook uses this to let the client reconstruct
+// conversation order even when References/In-Reply-To are missing, so
+// forging a plausible one helps a message blend into an existing thread
+// view.
+func threadIndex() string {
+	// FILETIME is 100ns ticks since 1601-01-01. We only need it to look
+	// right, so derive it from the current time.
+	const filetimeEpochOffset = 116444736000000000 // 1601-01-01 -> 1970-01-01, in 100ns ticks
+	filetime := uint64(time.Now().UnixNano()/100) + filetimeEpochOffset
+
+	blob := make([]byte, 27)
+	blob[0] = 1 // header block is always 0x01 for the first 5 bits
+	blob[1] = byte(filetime >> 40)
+	blob[2] = byte(filetime >> 32)
+	blob[3] = byte(filetime >> 24)
+	blob[4] = byte(filetime >> 16)
+	blob[5] = byte(filetime >> 8)
+	// Remaining 16 bytes of the header block are a random per-thread GUID.
+	copy(blob[6:22], []byte(randomHex(16)))
+	// 5-byte child block: one random block per reply in the thread; we
+	// only ever emit the root message, so a single random block suffices.
+	copy(blob[22:27], []byte(randomHex(5)))
+
+	return base64.StdEncoding.EncodeToString(blob)
+}
@@ -0,0 +1,121 @@
+package headers
+
+import (
+	"time"
+
+	"github.com/gophish/gomail"
+)
+
+func init() {
+	Register("default", defaultProfile{})
+	Register("apple_mail", appleMailProfile{})
+	Register("outlook", outlookProfile{})
+	Register("gmail_web", gmailWebProfile{})
+	Register("yahoo_web", yahooWebProfile{})
+}
+
+// defaultProfile is Gophish's own profile: MIME-Version and a generic
+// Message-ID, with no attempt to mimic a specific client.
+type defaultProfile struct{}
+
+func (defaultProfile) Name() string { return "Default (Gophish)" }
+
+func (defaultProfile) Apply(msg *gomail.Message, params RecipientParams, attachments bool) error {
+	host := mailHost(params.From)
+	headers := map[string]string{
+		"MIME-Version": "1.0",
+		"Date":         time.Now().Format(time.RFC1123Z),
+		"Message-Id":   defaultMessageID(host),
+	}
+	setOrdered(msg, []string{"MIME-Version", "Date", "Message-Id"}, headers)
+	return nil
+}
+
+// appleMailProfile mimics Apple Mail on macOS: a versioned X-Mailer and a
+// timezone-name Date header.
+type appleMailProfile struct{}
+
+func (appleMailProfile) Name() string { return "Apple Mail (macOS)" }
+
+func (appleMailProfile) Apply(msg *gomail.Message, params RecipientParams, attachments bool) error {
+	host := mailHost(params.From)
+	headers := map[string]string{
+		"MIME-Version": "1.0 (Mac OS X Mail 16.0 (3774.500.171.1.1))",
+		"X-Mailer":     "Apple Mail (2.3774.500.171.1.1)",
+		// Apple Mail renders the Date header with the zone's short name,
+		// e.g. "Thu, 26 Jun 2026 09:14:02 -0700 (PDT)".
+		"Date":       time.Now().Format("Mon, 2 Jan 2006 15:04:05 -0700 (MST)"),
+		"Message-Id": appleMailMessageID(host),
+	}
+	setOrdered(msg, []string{"MIME-Version", "X-Mailer", "Date", "Message-Id"}, headers)
+	return nil
+}
+
+// outlookProfile mimics Microsoft Outlook for Windows: X-Mailer,
+// Content-Language, Thread-Index, the TNEF correlator headers Outlook
+// tacks on by default, and a "-0000" variant Date that Outlook's SMTP
+// gateway stamps when it doesn't trust the local clock's offset.
+type outlookProfile struct{}
+
+func (outlookProfile) Name() string { return "Microsoft Outlook (Desktop)" }
+
+func (outlookProfile) Apply(msg *gomail.Message, params RecipientParams, attachments bool) error {
+	host := mailHost(params.From)
+	headers := map[string]string{
+		"MIME-Version":     "1.0",
+		"X-Mailer":         "Microsoft Outlook 16.0",
+		"Content-Language": "en-us",
+		// Outlook's desktop client emits "-0000" rather than the local
+		// zone offset for most outbound mail.
+		"Date":                 time.Now().Format("Mon, 2 Jan 2006 15:04:05 -0000"),
+		"Message-Id":           outlookMessageID(host),
+		"Thread-Index":         threadIndex(),
+		"x-ms-has-attach":      hasAttachValue(attachments),
+		"x-ms-tnef-correlator": "",
+	}
+	setOrdered(msg, []string{
+		"MIME-Version", "X-Mailer", "Content-Language", "Date", "Message-Id",
+		"Thread-Index", "x-ms-has-attach", "x-ms-tnef-correlator",
+	}, headers)
+	return nil
+}
+
+func hasAttachValue(attachments bool) string {
+	if attachments {
+		return "yes"
+	}
+	return ""
+}
+
+// gmailWebProfile mimics Gmail's web compose UI, which adds no
+// X-Mailer and uses Gmail's distinctive "CA...+hash@mail.gmail.com"
+// Message-ID format.
+type gmailWebProfile struct{}
+
+func (gmailWebProfile) Name() string { return "Gmail (Web Interface)" }
+
+func (gmailWebProfile) Apply(msg *gomail.Message, params RecipientParams, attachments bool) error {
+	headers := map[string]string{
+		"MIME-Version": "1.0",
+		"Date":         time.Now().Format(time.RFC1123Z),
+		"Message-Id":   gmailMessageID(params.Email),
+	}
+	setOrdered(msg, []string{"MIME-Version", "Date", "Message-Id"}, headers)
+	return nil
+}
+
+// yahooWebProfile mimics Yahoo Mail's web compose UI.
+type yahooWebProfile struct{}
+
+func (yahooWebProfile) Name() string { return "Yahoo Mail (Web Interface)" }
+
+func (yahooWebProfile) Apply(msg *gomail.Message, params RecipientParams, attachments bool) error {
+	host := mailHost(params.From)
+	headers := map[string]string{
+		"MIME-Version": "1.0",
+		"Date":         time.Now().Format(time.RFC1123Z),
+		"Message-Id":   defaultMessageID(host),
+	}
+	setOrdered(msg, []string{"MIME-Version", "Date", "Message-Id"}, headers)
+	return nil
+}
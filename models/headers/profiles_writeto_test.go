@@ -0,0 +1,114 @@
+package headers
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gophish/gomail"
+)
+
+// buildMessage returns a gomail.Message with a plain/HTML alternative
+// body plus an attachment, the shape that triggers gomail's nested
+// multipart/mixed(multipart/alternative) envelope -- the case where a
+// profile-supplied Content-Type would collide with gomail's own.
+func buildMessage() *gomail.Message {
+	msg := gomail.NewMessage()
+	msg.SetHeader("From", "sender@example.com")
+	msg.SetHeader("To", "target@example.com")
+	msg.SetHeader("Subject", "Hello")
+	msg.SetBody("text/plain", "hello plain")
+	msg.AddAlternative("text/html", "<p>hello html</p>")
+	msg.AttachReader("invoice.pdf", strings.NewReader("%PDF-1.4 fake"))
+	return msg
+}
+
+// topLevelHeaders returns the message's top-level header block (everything
+// before the first blank line), where WriteTo's own Content-Type/boundary
+// line would collide with one a profile stamped directly onto the message.
+func topLevelHeaders(t *testing.T, msg *gomail.Message) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if _, err := msg.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo returned error: %v", err)
+	}
+	out := buf.String()
+	if i := strings.Index(out, "\r\n\r\n"); i != -1 {
+		return out[:i]
+	}
+	return out
+}
+
+func TestProfilesDoNotDuplicateContentType(t *testing.T) {
+	for _, name := range []string{"default", "apple_mail", "outlook", "gmail_web", "yahoo_web"} {
+		t.Run(name, func(t *testing.T) {
+			msg := buildMessage()
+			params := RecipientParams{From: "sender@example.com", Email: "target@example.com"}
+			if err := Get(name).Apply(msg, params, true); err != nil {
+				t.Fatalf("Apply returned error: %v", err)
+			}
+
+			header := topLevelHeaders(t, msg)
+			count := strings.Count(header, "Content-Type:")
+			if count != 1 {
+				t.Fatalf("expected exactly one top-level Content-Type header, got %d:\n%s", count, header)
+			}
+			if !strings.Contains(header, "multipart/mixed") {
+				t.Fatalf("expected gomail's own multipart/mixed Content-Type, got:\n%s", header)
+			}
+			if !strings.Contains(header, "Message-Id:") {
+				t.Fatalf("expected profile to have stamped a Message-Id, got:\n%s", header)
+			}
+		})
+	}
+}
+
+func TestConfigProfileDoesNotDuplicateContentType(t *testing.T) {
+	p := configProfile{name: "custom", cfg: ProfileConfig{
+		Headers: map[string]string{"X-Mailer": "CustomMailer 1.0"},
+		Order:   []string{"X-Mailer", "Date", "Message-Id"},
+	}}
+	msg := buildMessage()
+	params := RecipientParams{From: "sender@example.com", Email: "target@example.com"}
+	if err := p.Apply(msg, params, true); err != nil {
+		t.Fatalf("Apply returned error: %v", err)
+	}
+
+	header := topLevelHeaders(t, msg)
+	if count := strings.Count(header, "Content-Type:"); count != 1 {
+		t.Fatalf("expected exactly one top-level Content-Type header, got %d:\n%s", count, header)
+	}
+	if !strings.Contains(header, "X-Mailer: CustomMailer 1.0") {
+		t.Fatalf("expected the custom X-Mailer header, got:\n%s", header)
+	}
+}
+
+func TestOutlookProfileThreadIndexIsStableLength(t *testing.T) {
+	msg := buildMessage()
+	params := RecipientParams{From: "sender@example.com", Email: "target@example.com"}
+	if err := Get("outlook").Apply(msg, params, true); err != nil {
+		t.Fatalf("Apply returned error: %v", err)
+	}
+	got := msg.GetHeader("Thread-Index")
+	if len(got) != 1 || got[0] == "" {
+		t.Fatalf("expected a single non-empty Thread-Index header, got %v", got)
+	}
+}
+
+func TestGmailMessageIDHashIsStablePerRecipient(t *testing.T) {
+	// The leading "CA<random>" portion is fresh every call; only the
+	// "+<hash>" suffix is meant to stay stable for a given recipient.
+	hash := recipientHash("victim@example.com")
+	first := gmailMessageID("victim@example.com")
+	second := gmailMessageID("victim@example.com")
+	if !strings.Contains(first, "+"+hash+"@mail.gmail.com") || !strings.Contains(second, "+"+hash+"@mail.gmail.com") {
+		t.Fatalf("expected both Message-IDs to embed the recipient hash %q: %q, %q", hash, first, second)
+	}
+	if first == second {
+		t.Fatalf("expected the random prefix to differ between calls")
+	}
+	otherHash := recipientHash("other@example.com")
+	if hash == otherHash {
+		t.Fatalf("expected different recipients to hash differently")
+	}
+}
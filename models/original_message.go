@@ -0,0 +1,220 @@
+package models
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Original carries the message a campaign is configured to reply to, so
+// a template can hijack an existing thread rather than starting a new
+// one -- e.g. "Re: {{.Original.Subject}}" with a quoted body via
+// {{quote .Original.Text}}. It's populated by ParseOriginalMessage from
+// a raw message the operator uploads or pastes in.
+type Original struct {
+	From      string
+	Date      time.Time
+	Subject   string
+	Text      string
+	MIMEType  string
+	MessageID string
+}
+
+// ParseOriginalMessage parses a raw RFC 5322 message (headers + body, as
+// an operator would paste it in or upload an .eml file) into an
+// Original, for use as PhishingTemplateContext.Original. Multipart
+// bodies -- the normal case for mail sent by Outlook, Gmail and Apple
+// Mail -- are walked to pull out a readable text/plain part, falling
+// back to a tag-stripped text/html part when no text/plain part exists.
+func ParseOriginalMessage(raw string) (Original, error) {
+	m, err := mail.ReadMessage(strings.NewReader(raw))
+	if err != nil {
+		return Original{}, fmt.Errorf("error parsing original message: %w", err)
+	}
+
+	contentType := m.Header.Get("Content-Type")
+	text, err := extractText(textproto.MIMEHeader(m.Header), m.Body)
+	if err != nil {
+		return Original{}, fmt.Errorf("error reading original message body: %w", err)
+	}
+
+	o := Original{
+		From:      m.Header.Get("From"),
+		Subject:   m.Header.Get("Subject"),
+		MessageID: strings.Trim(m.Header.Get("Message-Id"), "<>"),
+		Text:      text,
+		MIMEType:  originalMIMEType(contentType),
+	}
+	if d, err := m.Header.Date(); err == nil {
+		o.Date = d
+	}
+	return o, nil
+}
+
+// extractText returns the best plain-text representation of a message
+// part given its headers and body: the decoded body directly for a
+// non-multipart part, or the preferred sub-part's text when header
+// declares a multipart Content-Type.
+func extractText(header textproto.MIMEHeader, body io.Reader) (string, error) {
+	contentType := header.Get("Content-Type")
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		// No Content-Type, or an unparseable one: treat the body as
+		// plain text, matching the legacy behavior for simple messages.
+		return decodeBody(header.Get("Content-Transfer-Encoding"), body)
+	}
+
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		text, err := decodeBody(header.Get("Content-Transfer-Encoding"), body)
+		if err != nil {
+			return "", err
+		}
+		if mediaType == "text/html" {
+			return stripHTML(text), nil
+		}
+		return text, nil
+	}
+
+	boundary := params["boundary"]
+	if boundary == "" {
+		return decodeBody(header.Get("Content-Transfer-Encoding"), body)
+	}
+
+	var plainText, htmlText string
+	haveText, haveHTML := false, false
+
+	mr := multipart.NewReader(body, boundary)
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		partHeader := textproto.MIMEHeader(part.Header)
+		partType, _, err := mime.ParseMediaType(partHeader.Get("Content-Type"))
+		if err != nil {
+			partType = "text/plain"
+		}
+
+		if strings.HasPrefix(partType, "multipart/") {
+			// Nested multipart (e.g. multipart/mixed wrapping an inner
+			// multipart/alternative when there are attachments).
+			nested, err := extractText(partHeader, part)
+			if err != nil {
+				return "", err
+			}
+			if nested != "" && !haveText {
+				plainText = nested
+				haveText = true
+			}
+			continue
+		}
+
+		text, err := decodeBody(partHeader.Get("Content-Transfer-Encoding"), part)
+		if err != nil {
+			return "", err
+		}
+
+		switch partType {
+		case "text/plain":
+			plainText = text
+			haveText = true
+		case "text/html":
+			htmlText = text
+			haveHTML = true
+		}
+	}
+
+	if haveText {
+		return plainText, nil
+	}
+	if haveHTML {
+		return stripHTML(htmlText), nil
+	}
+	return "", nil
+}
+
+// decodeBody reads body fully, decoding it according to encoding (the
+// part or message's Content-Transfer-Encoding header value). An unknown
+// encoding is read verbatim rather than erroring out the whole parse.
+func decodeBody(encoding string, body io.Reader) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// stripHTML strips tags from an HTML fragment and unescapes entities,
+// producing a rough plain-text rendering suitable for {{quote}}. It's
+// not a full HTML renderer -- just enough to avoid dumping raw markup
+// into a quoted-reply body.
+func stripHTML(s string) string {
+	// <script>/<style> contents should be dropped entirely, not just
+	// their tags, since they're never meant to be read as text.
+	s = regexp.MustCompile(`(?is)<script[^>]*>.*?</script>`).ReplaceAllString(s, "")
+	s = regexp.MustCompile(`(?is)<style[^>]*>.*?</style>`).ReplaceAllString(s, "")
+	s = regexp.MustCompile(`(?i)<br\s*/?>`).ReplaceAllString(s, "\n")
+	s = regexp.MustCompile(`(?i)</p>`).ReplaceAllString(s, "\n\n")
+	s = regexp.MustCompile(`<[^>]+>`).ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	return strings.TrimSpace(s)
+}
+
+// originalMIMEType extracts the bare media type (e.g. "text/plain")
+// from a Content-Type header value, defaulting to "text/plain" when the
+// header is absent or unparseable.
+func originalMIMEType(contentType string) string {
+	if contentType == "" {
+		return "text/plain"
+	}
+	mimeType := strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])
+	if mimeType == "" {
+		return "text/plain"
+	}
+	return mimeType
+}
+
+// ReplyHeaders returns the In-Reply-To/References headers that the
+// mailer should stamp on a message replying to o, keyed by header name,
+// so the message threads into the original conversation instead of
+// starting a new one. It returns an empty map when o has no
+// Message-ID (e.g. the campaign isn't configured as a reply).
+func (o Original) ReplyHeaders() map[string]string {
+	if o.MessageID == "" {
+		return map[string]string{}
+	}
+	id := fmt.Sprintf("<%s>", o.MessageID)
+	return map[string]string{
+		"In-Reply-To": id,
+		"References":  id,
+	}
+}
+
+// quote prefixes every line of text with "> ", in the style MUAs use
+// when quoting the message being replied to.
+func quote(text string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = "> " + line
+	}
+	return strings.Join(lines, "\n")
+}
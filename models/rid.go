@@ -0,0 +1,222 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	log "github.com/gophish/gophish/logger"
+	"github.com/jinzhu/gorm"
+)
+
+// Raw RId query parameters are guessable/enumerable and conflate
+// identity with authorization: anyone who can guess or brute-force a
+// result's RId can mark it opened. Token wraps an RId in an HMAC-signed,
+// opaque blob so the phishing server can verify a visitor actually holds
+// a token this server minted, rather than trusting the RId on its face.
+
+// ridSecretSettingKey/ridPreviousSecretSettingKey are the Setting rows
+// the signing secret (and its predecessor, during a rotation's grace
+// period) are persisted under.
+const (
+	ridSecretSettingKey         = "rid_token_secret"
+	ridPreviousSecretSettingKey = "rid_token_secret_previous"
+)
+
+// ErrInvalidToken is returned when a token fails to parse or its HMAC
+// doesn't verify against either the current or previous server secret.
+var ErrInvalidToken = errors.New("invalid or tampered recipient token")
+
+// tokenMaxAge bounds how long a signed token is accepted after it was
+// minted. It's generous enough to cover a long-running campaign (a
+// recipient opening a months-old reminder email) while still giving the
+// embedded timestamp some teeth against a token leaked or logged long
+// after the fact.
+const tokenMaxAge = 90 * 24 * time.Hour
+
+// tokenClockSkew allows a token to verify slightly ahead of the local
+// clock, to tolerate minor clock drift between servers in a cluster.
+const tokenClockSkew = 5 * time.Minute
+
+// GetServerSecret returns the server's current RId-signing secret,
+// generating and persisting a random one on first boot.
+func GetServerSecret() ([]byte, error) {
+	return getOrCreateSecret(ridSecretSettingKey)
+}
+
+// RotateServerSecret generates a new signing secret, moves the current
+// one to the "previous" slot (so tokens already in flight keep
+// verifying for one grace period), and persists the new one as current.
+// It's intended to be called from an admin API endpoint.
+func RotateServerSecret() error {
+	current, err := GetServerSecret()
+	if err != nil {
+		return err
+	}
+	if err := setSetting(ridPreviousSecretSettingKey, current); err != nil {
+		return err
+	}
+	next, err := randomSecret()
+	if err != nil {
+		return err
+	}
+	return setSetting(ridSecretSettingKey, next)
+}
+
+func getOrCreateSecret(key string) ([]byte, error) {
+	existing, err := getSetting(key)
+	if err == nil && len(existing) > 0 {
+		return existing, nil
+	}
+	secret, err := randomSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := setSetting(key, secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+func randomSecret() ([]byte, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("error generating server secret: %w", err)
+	}
+	return secret, nil
+}
+
+func getSetting(key string) ([]byte, error) {
+	s := Setting{}
+	err := db.Where("key=?", key).Find(&s).Error
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(s.Value)
+}
+
+// setSetting upserts the Setting row for key: gorm v1's Save only issues
+// an UPDATE when the primary key is already populated, so writing a
+// fresh Setting{} with a zero Id always INSERTs, which violates Key's
+// unique_index the moment the row already exists (e.g. every
+// RotateServerSecret call after the first). Looking up the existing
+// row's Id first makes this an update-in-place when key is already set.
+func setSetting(key string, value []byte) error {
+	s := Setting{Key: key, Value: base64.StdEncoding.EncodeToString(value)}
+	existing := Setting{}
+	err := db.Where("key=?", key).Find(&existing).Error
+	if err == nil {
+		s.Id = existing.Id
+	} else if err != gorm.ErrRecordNotFound {
+		log.Error(err)
+		return err
+	}
+	if err := db.Save(&s).Error; err != nil {
+		log.Error(err)
+		return err
+	}
+	return nil
+}
+
+// GenerateToken returns an opaque, HMAC-signed token for rid:
+//
+//	base64url(len(rid) || rid || timestamp || HMAC-SHA256(secret, len(rid)||rid||timestamp))
+//
+// The length prefix makes the rid unambiguous to recover on decode,
+// since rid itself may contain arbitrary characters.
+func GenerateToken(rid string) (string, error) {
+	secret, err := GetServerSecret()
+	if err != nil {
+		return "", err
+	}
+	return signToken(rid, secret), nil
+}
+
+func signToken(rid string, secret []byte) string {
+	payload := tokenPayload(rid)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	blob := append(payload, mac.Sum(nil)...)
+	return base64.RawURLEncoding.EncodeToString(blob)
+}
+
+// tokenPayload builds the len(rid)||rid||timestamp portion of a token,
+// shared by both signing and verification.
+func tokenPayload(rid string) []byte {
+	payload := make([]byte, 0, 1+len(rid)+8)
+	payload = append(payload, byte(len(rid)))
+	payload = append(payload, []byte(rid)...)
+	ts := make([]byte, 8)
+	binary.BigEndian.PutUint64(ts, uint64(time.Now().Unix()))
+	payload = append(payload, ts...)
+	return payload
+}
+
+// VerifyToken parses and verifies token, returning the RId it was
+// minted for. It accepts tokens signed with either the current server
+// secret or the previous one (if a rotation happened within the last
+// grace period), so an in-flight campaign isn't invalidated by a
+// rotation.
+func VerifyToken(token string) (string, error) {
+	blob, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+	if len(blob) < 1 {
+		return "", ErrInvalidToken
+	}
+	ridLen := int(blob[0])
+	// 1 length byte + ridLen rid bytes + 8 timestamp bytes + 32 HMAC bytes
+	if len(blob) != 1+ridLen+8+sha256.Size {
+		return "", ErrInvalidToken
+	}
+	payload := blob[:1+ridLen+8]
+	mac := blob[1+ridLen+8:]
+	rid := string(blob[1 : 1+ridLen])
+	ts := time.Unix(int64(binary.BigEndian.Uint64(blob[1+ridLen:1+ridLen+8])), 0)
+
+	now := time.Now()
+	if ts.Before(now.Add(-tokenMaxAge)) || ts.After(now.Add(tokenClockSkew)) {
+		return "", ErrInvalidToken
+	}
+
+	current, err := GetServerSecret()
+	if err == nil && validMAC(payload, mac, current) {
+		return rid, nil
+	}
+	previous, err := getSetting(ridPreviousSecretSettingKey)
+	if err == nil && validMAC(payload, mac, previous) {
+		return rid, nil
+	}
+	return "", ErrInvalidToken
+}
+
+func validMAC(payload, mac, secret []byte) bool {
+	if len(secret) == 0 {
+		return false
+	}
+	expected := hmac.New(sha256.New, secret)
+	expected.Write(payload)
+	return hmac.Equal(mac, expected.Sum(nil))
+}
+
+// ResolveRecipientID recovers an RId from a value supplied on an
+// incoming phishing/tracking request: it first tries to verify value as
+// a signed Token, and, only when allowLegacyRawRID is true (a
+// compatibility flag for campaigns launched before this subsystem
+// existed), falls back to treating value as a raw, unsigned RId.
+func ResolveRecipientID(value string, allowLegacyRawRID bool) (string, error) {
+	rid, err := VerifyToken(value)
+	if err == nil {
+		return rid, nil
+	}
+	if allowLegacyRawRID {
+		return value, nil
+	}
+	return "", err
+}
@@ -0,0 +1,79 @@
+package models
+
+import "testing"
+
+func TestVerifyTokenRejectsTamperedToken(t *testing.T) {
+	token, err := GenerateToken("42")
+	if err != nil {
+		t.Fatalf("GenerateToken returned error: %v", err)
+	}
+	tampered := token[:len(token)-2] + "zz"
+	if tampered == token {
+		t.Fatalf("test setup didn't actually tamper with the token")
+	}
+	if _, err := VerifyToken(tampered); err != ErrInvalidToken {
+		t.Fatalf("expected ErrInvalidToken for a tampered token, got %v", err)
+	}
+}
+
+func TestVerifyTokenRoundTrip(t *testing.T) {
+	token, err := GenerateToken("99")
+	if err != nil {
+		t.Fatalf("GenerateToken returned error: %v", err)
+	}
+	rid, err := VerifyToken(token)
+	if err != nil {
+		t.Fatalf("VerifyToken returned error for a validly signed token: %v", err)
+	}
+	if rid != "99" {
+		t.Fatalf("expected rid %q, got %q", "99", rid)
+	}
+}
+
+func TestResolveRecipientIDLegacyFallback(t *testing.T) {
+	const rawRID = "legacy-rid-123"
+
+	if _, err := ResolveRecipientID(rawRID, false); err == nil {
+		t.Fatalf("expected a raw RId to fail verification when the compatibility flag is off")
+	}
+
+	rid, err := ResolveRecipientID(rawRID, true)
+	if err != nil {
+		t.Fatalf("ResolveRecipientID with allowLegacyRawRID=true returned error: %v", err)
+	}
+	if rid != rawRID {
+		t.Fatalf("expected the raw RId %q back, got %q", rawRID, rid)
+	}
+}
+
+func TestResolveRecipientIDPrefersSignedToken(t *testing.T) {
+	token, err := GenerateToken("7")
+	if err != nil {
+		t.Fatalf("GenerateToken returned error: %v", err)
+	}
+	// A signed token should resolve correctly even with the legacy
+	// compatibility flag off, since it never needs the raw-RId fallback.
+	rid, err := ResolveRecipientID(token, false)
+	if err != nil {
+		t.Fatalf("ResolveRecipientID returned error for a validly signed token: %v", err)
+	}
+	if rid != "7" {
+		t.Fatalf("expected rid %q, got %q", "7", rid)
+	}
+}
+
+func TestRotateServerSecretTwice(t *testing.T) {
+	// GetServerSecret auto-creates the secret row on first use, so
+	// rotating twice in a row exercises setSetting's update path against
+	// a row that already exists -- the case that used to violate
+	// Setting.Key's unique_index.
+	if _, err := GetServerSecret(); err != nil {
+		t.Fatalf("GetServerSecret returned error: %v", err)
+	}
+	if err := RotateServerSecret(); err != nil {
+		t.Fatalf("first RotateServerSecret returned error: %v", err)
+	}
+	if err := RotateServerSecret(); err != nil {
+		t.Fatalf("second RotateServerSecret returned error: %v", err)
+	}
+}
@@ -0,0 +1,11 @@
+package models
+
+// Setting is a generic key/value row used to persist small pieces of
+// server-wide state -- such as the RId token signing secret -- that
+// don't warrant a dedicated table. Values are stored as strings; binary
+// values (like secrets) are base64 encoded by the caller.
+type Setting struct {
+	Id    int64  `json:"-" gorm:"column:id; primary_key:yes"`
+	Key   string `json:"key" gorm:"column:key; unique_index"`
+	Value string `json:"value" gorm:"column:value"`
+}
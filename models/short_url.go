@@ -0,0 +1,83 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"path"
+	"time"
+
+	log "github.com/gophish/gophish/logger"
+)
+
+// shortURLTokenBytes controls the length of a generated short-URL token
+// before base64 encoding. 9 bytes gives a 12-character URL-safe token,
+// short enough to fit comfortably in an SMS body.
+const shortURLTokenBytes = 9
+
+// ShortURL maps a short, SMS-friendly token to the full tracked
+// phishing URL it redirects to. SMS bodies can't carry a hidden <img>
+// tracker the way email can, so a click on the ShortURL is the only
+// engagement signal a smishing campaign gets; resolving it is what
+// records that click.
+type ShortURL struct {
+	Id          int64     `json:"-" gorm:"column:id; primary_key:yes"`
+	Token       string    `json:"token" gorm:"column:token; unique_index"`
+	TargetURL   string    `json:"target_url" gorm:"column:target_url"`
+	RId         string    `json:"rid" gorm:"column:rid"`
+	CreatedDate time.Time `json:"created_date"`
+}
+
+// ErrShortURLNotFound is returned when a token doesn't resolve to a
+// known ShortURL.
+var ErrShortURLNotFound = errors.New("Short URL not found")
+
+// generateShortURLToken returns a random, URL-safe token suitable for
+// use in an `/s/{token}` path segment.
+func generateShortURLToken() (string, error) {
+	b := make([]byte, shortURLTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CreateShortURL persists a new short URL pointing at targetURL for the
+// given recipient id, returning the token to embed in the SMS body as
+// `{{.BaseURL}}/s/{token}`.
+func CreateShortURL(targetURL string, rid string) (ShortURL, error) {
+	token, err := generateShortURLToken()
+	if err != nil {
+		return ShortURL{}, err
+	}
+	su := ShortURL{
+		Token:       token,
+		TargetURL:   targetURL,
+		RId:         rid,
+		CreatedDate: time.Now().UTC(),
+	}
+	if err := db.Save(&su).Error; err != nil {
+		log.Error(err)
+		return ShortURL{}, err
+	}
+	return su, nil
+}
+
+// GetShortURL looks up the ShortURL registered under token. The
+// phishing server's `/s/{token}` handler (not part of this package) is
+// expected to call this, record the click against su.RId, and 302 the
+// visitor to su.TargetURL.
+func GetShortURL(token string) (ShortURL, error) {
+	su := ShortURL{}
+	err := db.Where("token=?", token).Find(&su).Error
+	if err != nil {
+		log.Error(err)
+		return su, ErrShortURLNotFound
+	}
+	return su, nil
+}
+
+// shortURLPath builds the `/s/{token}` path appended to a base URL.
+func shortURLPath(token string) string {
+	return path.Join("/s", token)
+}
@@ -0,0 +1,29 @@
+package models
+
+import "testing"
+
+func TestGenerateShortURLTokenIsURLSafeAndUnique(t *testing.T) {
+	first, err := generateShortURLToken()
+	if err != nil {
+		t.Fatalf("generateShortURLToken returned error: %v", err)
+	}
+	second, err := generateShortURLToken()
+	if err != nil {
+		t.Fatalf("generateShortURLToken returned error: %v", err)
+	}
+	if first == second {
+		t.Fatalf("expected two generated tokens to differ")
+	}
+	for _, c := range first {
+		if c == '+' || c == '/' || c == '=' {
+			t.Fatalf("expected a URL-safe token, got %q", first)
+		}
+	}
+}
+
+func TestShortURLPathJoinsTokenUnderSPrefix(t *testing.T) {
+	got := shortURLPath("abc123")
+	if got != "/s/abc123" {
+		t.Fatalf("shortURLPath: got %q, want %q", got, "/s/abc123")
+	}
+}
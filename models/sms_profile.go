@@ -0,0 +1,135 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	log "github.com/gophish/gophish/logger"
+)
+
+// SMSProvider identifies which transport an SMSProfile talks to.
+type SMSProvider string
+
+const (
+	// SMSProviderTwilio sends via the Twilio Programmable Messaging API.
+	SMSProviderTwilio SMSProvider = "twilio"
+	// SMSProviderVonage sends via the Vonage (Nexmo) SMS API.
+	SMSProviderVonage SMSProvider = "vonage"
+	// SMSProviderSMPP sends via a generic SMPP bind, for operators
+	// running their own gateway.
+	SMSProviderSMPP SMSProvider = "smpp"
+)
+
+// SMSProfile represents the configuration for an SMS sending profile,
+// mirroring the role SMTP plays for email: a named, reusable set of
+// transport credentials a Campaign can be pointed at.
+type SMSProfile struct {
+	Id         int64       `json:"id" gorm:"column:id; primary_key:yes"`
+	UserId     int64       `json:"-" gorm:"column:user_id"`
+	Name       string      `json:"name"`
+	Provider   SMSProvider `json:"provider"`
+	FromNumber string      `json:"from_number"`
+	// AccountSID/AuthToken are used by the Twilio and Vonage providers.
+	AccountSID string `json:"account_sid,omitempty"`
+	AuthToken  string `json:"auth_token,omitempty"`
+	// Host/Port/SystemID/Password are used by the SMPP provider.
+	Host         string    `json:"host,omitempty"`
+	Port         int       `json:"port,omitempty"`
+	SystemID     string    `json:"system_id,omitempty"`
+	Password     string    `json:"password,omitempty"`
+	ModifiedDate time.Time `json:"modified_date"`
+}
+
+// ErrSMSProfileNameNotSpecified is thrown when an SMS profile name is
+// not specified.
+var ErrSMSProfileNameNotSpecified = errors.New("SMS profile name not specified")
+
+// ErrSMSProfileFromNotSpecified is thrown when an SMS profile has no
+// sending number.
+var ErrSMSProfileFromNotSpecified = errors.New("SMS profile must specify a from number")
+
+// ErrInvalidSMSProvider is thrown when a Provider other than the known
+// constants is supplied.
+var ErrInvalidSMSProvider = errors.New("Invalid SMS provider specified")
+
+// ErrSMSProfileMissingCredentials is thrown when a provider's
+// required credential fields are empty.
+var ErrSMSProfileMissingCredentials = errors.New("SMS profile is missing required credentials for the selected provider")
+
+// Validate checks the given SMS profile to make sure values are
+// appropriate and complete for the selected provider.
+func (s *SMSProfile) Validate() error {
+	switch {
+	case s.Name == "":
+		return ErrSMSProfileNameNotSpecified
+	case s.FromNumber == "":
+		return ErrSMSProfileFromNotSpecified
+	}
+	switch s.Provider {
+	case SMSProviderTwilio, SMSProviderVonage:
+		if s.AccountSID == "" || s.AuthToken == "" {
+			return ErrSMSProfileMissingCredentials
+		}
+	case SMSProviderSMPP:
+		if s.Host == "" || s.Port == 0 || s.SystemID == "" {
+			return ErrSMSProfileMissingCredentials
+		}
+	default:
+		return ErrInvalidSMSProvider
+	}
+	return nil
+}
+
+// GetSMSProfiles returns the SMS profiles owned by the given user.
+func GetSMSProfiles(uid int64) ([]SMSProfile, error) {
+	ps := []SMSProfile{}
+	err := db.Where("user_id=?", uid).Find(&ps).Error
+	if err != nil {
+		log.Error(err)
+	}
+	return ps, err
+}
+
+// GetSMSProfile returns the SMS profile, if it exists, specified by the
+// given id and user_id.
+func GetSMSProfile(id int64, uid int64) (SMSProfile, error) {
+	p := SMSProfile{}
+	err := db.Where("user_id=? and id=?", uid, id).Find(&p).Error
+	if err != nil {
+		log.Error(err)
+	}
+	return p, err
+}
+
+// PostSMSProfile creates a new SMS profile in the database.
+func PostSMSProfile(p *SMSProfile) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	err := db.Save(p).Error
+	if err != nil {
+		log.Error(err)
+	}
+	return err
+}
+
+// PutSMSProfile edits an existing SMS profile in the database.
+func PutSMSProfile(p *SMSProfile) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	err := db.Save(p).Error
+	if err != nil {
+		log.Error(err)
+	}
+	return err
+}
+
+// DeleteSMSProfile deletes an existing SMS profile in the database.
+func DeleteSMSProfile(id int64, uid int64) error {
+	err := db.Where("user_id=?", uid).Delete(&SMSProfile{Id: id}).Error
+	if err != nil {
+		log.Error(err)
+	}
+	return err
+}
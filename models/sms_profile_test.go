@@ -0,0 +1,62 @@
+package models
+
+import "testing"
+
+func TestSMSProfileValidateRequiresNameAndFromNumber(t *testing.T) {
+	p := SMSProfile{FromNumber: "+15555550100"}
+	if err := p.Validate(); err != ErrSMSProfileNameNotSpecified {
+		t.Fatalf("expected ErrSMSProfileNameNotSpecified, got %v", err)
+	}
+
+	p = SMSProfile{Name: "Twilio Prod"}
+	if err := p.Validate(); err != ErrSMSProfileFromNotSpecified {
+		t.Fatalf("expected ErrSMSProfileFromNotSpecified, got %v", err)
+	}
+}
+
+func TestSMSProfileValidateRejectsUnknownProvider(t *testing.T) {
+	p := SMSProfile{Name: "Mystery", FromNumber: "+15555550100", Provider: SMSProvider("carrier-pigeon")}
+	if err := p.Validate(); err != ErrInvalidSMSProvider {
+		t.Fatalf("expected ErrInvalidSMSProvider, got %v", err)
+	}
+}
+
+func TestSMSProfileValidateTwilioRequiresCredentials(t *testing.T) {
+	p := SMSProfile{Name: "Twilio Prod", FromNumber: "+15555550100", Provider: SMSProviderTwilio}
+	if err := p.Validate(); err != ErrSMSProfileMissingCredentials {
+		t.Fatalf("expected ErrSMSProfileMissingCredentials, got %v", err)
+	}
+
+	p.AccountSID = "AC123"
+	p.AuthToken = "secret"
+	if err := p.Validate(); err != nil {
+		t.Fatalf("expected a fully-configured Twilio profile to validate, got %v", err)
+	}
+}
+
+func TestSMSProfileValidateVonageRequiresCredentials(t *testing.T) {
+	p := SMSProfile{Name: "Vonage Prod", FromNumber: "+15555550100", Provider: SMSProviderVonage}
+	if err := p.Validate(); err != ErrSMSProfileMissingCredentials {
+		t.Fatalf("expected ErrSMSProfileMissingCredentials, got %v", err)
+	}
+
+	p.AccountSID = "key"
+	p.AuthToken = "secret"
+	if err := p.Validate(); err != nil {
+		t.Fatalf("expected a fully-configured Vonage profile to validate, got %v", err)
+	}
+}
+
+func TestSMSProfileValidateSMPPRequiresHostPortSystemID(t *testing.T) {
+	p := SMSProfile{Name: "Own Gateway", FromNumber: "+15555550100", Provider: SMSProviderSMPP}
+	if err := p.Validate(); err != ErrSMSProfileMissingCredentials {
+		t.Fatalf("expected ErrSMSProfileMissingCredentials, got %v", err)
+	}
+
+	p.Host = "smpp.example.com"
+	p.Port = 2775
+	p.SystemID = "gophish"
+	if err := p.Validate(); err != nil {
+		t.Fatalf("expected a fully-configured SMPP profile to validate, got %v", err)
+	}
+}
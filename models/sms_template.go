@@ -0,0 +1,129 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"time"
+
+	log "github.com/gophish/gophish/logger"
+)
+
+// SMSTemplate models the attributes of an SMS message to be sent to
+// targets. It intentionally carries far less than Template: SMS has no
+// MIME structure, no attachments and no header profile, so there's
+// nothing to mirror from the email side beyond Name/Body/ownership.
+type SMSTemplate struct {
+	Id           int64     `json:"id" gorm:"column:id; primary_key:yes"`
+	UserId       int64     `json:"-" gorm:"column:user_id"`
+	Name         string    `json:"name"`
+	Body         string    `json:"body"`
+	ModifiedDate time.Time `json:"modified_date"`
+}
+
+// ErrSMSTemplateNameNotSpecified is thrown when an SMS template name is
+// not specified.
+var ErrSMSTemplateNameNotSpecified = errors.New("SMS template name not specified")
+
+// ErrSMSTemplateMissingParameter is thrown when an SMS template is saved
+// without a body.
+var ErrSMSTemplateMissingParameter = errors.New("Need to specify a message body")
+
+// ErrSMSTemplateContainsHTML is thrown when an SMS template body contains
+// HTML or the tracking image tag; SMS bodies are plain text and can't
+// carry a hidden <img> tracker, so a click on the ShortURL is the only
+// open/engagement signal available.
+var ErrSMSTemplateContainsHTML = errors.New("SMS template body cannot contain HTML")
+
+// smsHTMLPattern flags anything that looks like an HTML tag, including
+// the tracker <img> Gophish injects into email templates.
+var smsHTMLPattern = regexp.MustCompile(`<[a-zA-Z/][^>]*>`)
+
+// smsLengthWarningThreshold is the length, in characters, above which a
+// single SMS segment is split by carriers into multiple concatenated
+// messages. Gophish doesn't reject long bodies outright, since some
+// carriers support long messages fine, but it warns so operators know
+// their "SMS" may arrive as several texts.
+const smsLengthWarningThreshold = 160
+
+// Validate checks the given SMS template to make sure values are
+// appropriate and complete.
+func (t *SMSTemplate) Validate() error {
+	switch {
+	case t.Name == "":
+		return ErrSMSTemplateNameNotSpecified
+	case t.Body == "":
+		return ErrSMSTemplateMissingParameter
+	}
+	return ValidateSMSTemplate(t.Body)
+}
+
+// ValidateSMSTemplate ensures body is suitable for SMS delivery: it
+// rejects HTML/tracker tags outright (SMS has no renderer for them) and
+// logs a warning, rather than an error, when the body is long enough
+// that carriers will split it into multiple segments. It validates the
+// body through the SMS-channel template context (IsSMS true, ShortURL
+// populated) rather than the email one, so a body that depends on
+// {{if .IsSMS}}/{{.ShortURL}} is actually exercised.
+func ValidateSMSTemplate(body string) error {
+	if smsHTMLPattern.MatchString(body) {
+		return ErrSMSTemplateContainsHTML
+	}
+	if len(body) > smsLengthWarningThreshold {
+		log.Warn("SMS template body exceeds 160 characters and will be sent as multiple concatenated segments")
+	}
+	return validateTemplate(body, nil, ChannelSMS)
+}
+
+// GetSMSTemplates returns the SMS templates owned by the given user.
+func GetSMSTemplates(uid int64) ([]SMSTemplate, error) {
+	ts := []SMSTemplate{}
+	err := db.Where("user_id=?", uid).Find(&ts).Error
+	if err != nil {
+		log.Error(err)
+	}
+	return ts, err
+}
+
+// GetSMSTemplate returns the SMS template, if it exists, specified by
+// the given id and user_id.
+func GetSMSTemplate(id int64, uid int64) (SMSTemplate, error) {
+	t := SMSTemplate{}
+	err := db.Where("user_id=? and id=?", uid, id).Find(&t).Error
+	if err != nil {
+		log.Error(err)
+	}
+	return t, err
+}
+
+// PostSMSTemplate creates a new SMS template in the database.
+func PostSMSTemplate(t *SMSTemplate) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	err := db.Save(t).Error
+	if err != nil {
+		log.Error(err)
+	}
+	return err
+}
+
+// PutSMSTemplate edits an existing SMS template in the database.
+func PutSMSTemplate(t *SMSTemplate) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	err := db.Save(t).Error
+	if err != nil {
+		log.Error(err)
+	}
+	return err
+}
+
+// DeleteSMSTemplate deletes an existing SMS template in the database.
+func DeleteSMSTemplate(id int64, uid int64) error {
+	err := db.Where("user_id=?", uid).Delete(&SMSTemplate{Id: id}).Error
+	if err != nil {
+		log.Error(err)
+	}
+	return err
+}
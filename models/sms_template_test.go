@@ -0,0 +1,45 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSMSTemplateValidateRequiresNameAndBody(t *testing.T) {
+	tpl := SMSTemplate{Body: "hi"}
+	if err := tpl.Validate(); err != ErrSMSTemplateNameNotSpecified {
+		t.Fatalf("expected ErrSMSTemplateNameNotSpecified, got %v", err)
+	}
+
+	tpl = SMSTemplate{Name: "Reminder"}
+	if err := tpl.Validate(); err != ErrSMSTemplateMissingParameter {
+		t.Fatalf("expected ErrSMSTemplateMissingParameter, got %v", err)
+	}
+}
+
+func TestValidateSMSTemplateRejectsHTML(t *testing.T) {
+	err := ValidateSMSTemplate("Click here: <img src=\"x\"> {{.ShortURL}}")
+	if err != ErrSMSTemplateContainsHTML {
+		t.Fatalf("expected ErrSMSTemplateContainsHTML, got %v", err)
+	}
+}
+
+func TestValidateSMSTemplateAcceptsPlainBody(t *testing.T) {
+	if err := ValidateSMSTemplate("Your package is delayed. Track it: {{.ShortURL}}"); err != nil {
+		t.Fatalf("expected a plain SMS body to validate, got %v", err)
+	}
+}
+
+func TestValidateSMSTemplateWarnsButAllowsLongBody(t *testing.T) {
+	body := strings.Repeat("a", smsLengthWarningThreshold+1)
+	if err := ValidateSMSTemplate(body); err != nil {
+		t.Fatalf("expected a long body to validate (only logs a warning), got %v", err)
+	}
+}
+
+func TestSMSTemplateValidateRunsBodyThroughSMSChannel(t *testing.T) {
+	tpl := SMSTemplate{Name: "Delivery", Body: "{{if .IsSMS}}track: {{.ShortURL}}{{end}}"}
+	if err := tpl.Validate(); err != nil {
+		t.Fatalf("expected an SMS-channel conditional body to validate, got %v", err)
+	}
+}
@@ -8,7 +8,9 @@ import (
 	// "strings" // Removido - não utilizado
 	"time"
 
+	"github.com/gophish/gomail"
 	log "github.com/gophish/gophish/logger"
+	"github.com/gophish/gophish/models/headers"
 	"github.com/jinzhu/gorm"
 )
 
@@ -129,10 +131,11 @@ func GetHeadersForProfile(profileName string) (map[string]string, string, error)
 
 // Validate checks the given template to make sure values are appropriate and complete
 func (t *Template) Validate() error {
-	// Ensure HeaderProfile is valid if provided
+	// Ensure HeaderProfile is valid if provided. headers.Registered covers
+	// both the built-in profiles and any loaded from config.json via
+	// LoadHeaderProfiles, unlike the legacy PredefinedHeaderProfiles map.
 	 if t.HeaderProfile != "" {
-	 	 _, ok := PredefinedHeaderProfiles[t.HeaderProfile]
-	 	 if !ok {
+	 	 if !headers.Registered(t.HeaderProfile) {
 	 	 	 return ErrInvalidHeaderProfile
 	 	 }
 	 }
@@ -148,10 +151,10 @@ func (t *Template) Validate() error {
 	 	 	 return err
 	 	 }
 	 }
-	 if err := ValidateTemplate(t.HTML); err != nil {
+	 if err := ValidateTemplateForUser(t.HTML, t.UserId); err != nil {
 	 	 return err
 	 }
-	 if err := ValidateTemplate(t.Text); err != nil {
+	 if err := ValidateTemplateForUser(t.Text, t.UserId); err != nil {
 	 	 return err
 	 }
 	 for _, a := range t.Attachments {
@@ -332,3 +335,29 @@ func DeleteTemplate(id int64, uid int64) error {
 	 return nil
 }
 
+// LoadHeaderProfiles loads extra header profiles (keyed by name) from
+// the "header_profiles" object of the config.json at path, so operators
+// can add new MUA fingerprints without recompiling. It should be called
+// once at startup, alongside the rest of Gophish's config.json loading.
+func LoadHeaderProfiles(path string) error {
+	return headers.LoadFromFile(path)
+}
+
+// ApplyHeaderProfile stamps msg with t's configured HeaderProfile,
+// translating ctx into the minimal headers.RecipientParams the profile
+// subsystem needs. The mailer send path should call this immediately
+// before handing msg off to the SMTP client, so Template.HeaderProfile
+// fully controls the outgoing envelope.
+//
+// It uses ctx.FromAddress rather than ctx.From: From is the display
+// name (e.g. "IT Support" for a spoofed "IT Support <it@company.com>"
+// sender), which mailHost can't parse as an address, silently
+// collapsing every generated Message-Id host to "localhost".
+// FromAddress is always the bare envelope address.
+func ApplyHeaderProfile(msg *gomail.Message, t Template, ctx PhishingTemplateContext, attachments bool) error {
+	params := headers.RecipientParams{
+		From:  ctx.FromAddress,
+		Email: ctx.Email,
+	}
+	return headers.Get(t.HeaderProfile).Apply(msg, params, attachments)
+}
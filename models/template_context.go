@@ -20,7 +20,13 @@ type TemplateContext interface {
 // PhishingTemplateContext is the context that is sent to any template, such
 // as the email or landing page content.
 type PhishingTemplateContext struct {
-	From        string
+	From string
+	// FromAddress is the bare envelope address behind From, e.g.
+	// "it@company.com" when From is the spoofed display name
+	// "IT Support <it@company.com>". Callers that need a real,
+	// parseable address to derive something from (e.g. a Message-ID's
+	// host) should use this instead of From.
+	FromAddress string
 	URL         string
 	Tracker     string
 	TrackingURL string
@@ -30,13 +36,59 @@ type PhishingTemplateContext struct {
 	// Deprecated: Use {{.Recipient.Id}} instead for consistency
 	// Note: Keeping RId for backward compatibility for now
 	 RId         string
-	BaseURL     string
+	// Token is an HMAC-signed, opaque stand-in for RId used in URL and
+	// TrackingURL. Prefer it over RId everywhere except templates still
+	// relying on the deprecated {{.RId}} variable.
+	Token   string
+	BaseURL string
+	// IsSMS is true when this context is being rendered for an
+	// SMSTemplate rather than an email Template. Templates can check
+	// {{if .IsSMS}} to render channel-specific copy from a single body.
+	IsSMS bool
+	// ShortURL is a short, SMS-friendly link that 302s to URL. It's only
+	// populated when IsSMS is true, since email can rely on the Tracker
+	// image instead and has no length pressure on the visible link.
+	ShortURL string
+	// Original carries the prior message a campaign is configured to
+	// reply to, so a template can hijack an existing thread with
+	// {{.Original.Subject}}/{{quote .Original.Text}} rather than
+	// starting a new one.
+	Original Original
 	BaseRecipient
 }
 
-// NewPhishingTemplateContext returns a populated PhishingTemplateContext,
-// parsing the correct fields from the provided TemplateContext and recipient.
+// NewPhishingTemplateContext returns a populated PhishingTemplateContext
+// for an email Template, parsing the correct fields from the provided
+// TemplateContext and recipient. It signs rid into a real token via
+// GenerateToken, which requires a live DB connection to load the
+// server's signing secret.
 func NewPhishingTemplateContext(ctx TemplateContext, r BaseRecipient, rid string) (PhishingTemplateContext, error) {
+	token, err := GenerateToken(rid)
+	if err != nil {
+		return PhishingTemplateContext{}, fmt.Errorf("failed to generate recipient token: %w", err)
+	}
+	return newPhishingTemplateContext(ctx, r, rid, ChannelEmail, token, true)
+}
+
+// NewSMSPhishingTemplateContext returns a populated PhishingTemplateContext
+// for an SMSTemplate. Unlike the email path, it skips Tracker generation
+// (SMS bodies can't carry a hidden <img> tag) and populates ShortURL with
+// a short link that 302s to the same tracked phishing URL.
+func NewSMSPhishingTemplateContext(ctx TemplateContext, r BaseRecipient, rid string) (PhishingTemplateContext, error) {
+	token, err := GenerateToken(rid)
+	if err != nil {
+		return PhishingTemplateContext{}, fmt.Errorf("failed to generate recipient token: %w", err)
+	}
+	return newPhishingTemplateContext(ctx, r, rid, ChannelSMS, token, true)
+}
+
+// newPhishingTemplateContext builds a PhishingTemplateContext out of an
+// already-signed token, rather than generating one itself, so that
+// validateTemplate can exercise this same code path without touching the
+// DB. persistShortURL controls whether the ChannelSMS branch actually
+// writes a ShortURL row (true for a real send) or fabricates one inline
+// (false for validation, where nothing is ever clicked).
+func newPhishingTemplateContext(ctx TemplateContext, r BaseRecipient, rid string, channel Channel, token string, persistShortURL bool) (PhishingTemplateContext, error) {
 	// Parse the From address
 	// Use GetSmtpFrom if available, otherwise fall back to getFromAddress
 	var fromAddr string
@@ -101,33 +153,55 @@ func NewPhishingTemplateContext(ctx TemplateContext, r BaseRecipient, rid string
 	baseURL.Path = ""
 	baseURL.RawQuery = ""
 
-	// Create the final phishing URL with the recipient ID
+	// Create the final phishing URL with the recipient token
 	phishURL, _ := url.Parse(templateURL) // Use the templated URL
 	q := phishURL.Query()
-	q.Set(RecipientParameter, rid)
+	q.Set(RecipientParameter, token)
 	phishURL.RawQuery = q.Encode()
 
 	// Create the tracking URL
 	trackingURL, _ := url.Parse(templateURL) // Use the templated URL
 	trackingURL.Path = path.Join(trackingURL.Path, "/track") // Append /track
-	// Use the same query parameters as the phishing URL (contains RId)
+	// Use the same query parameters as the phishing URL (contains the token)
 	trackingURL.RawQuery = q.Encode()
 
-	// Return the populated context
-	return PhishingTemplateContext{
+	ptx := PhishingTemplateContext{
 		BaseRecipient: r,
 		BaseURL:       baseURL.String(),
 		URL:           phishURL.String(),
 		TrackingURL:   trackingURL.String(),
-		// Generate the tracker image tag
-		// Ensure TrackingURL is properly escaped for HTML attribute
-		// Using simple string concatenation for now.
-		Tracker:       "<img alt='' style='display: none' src='" + trackingURL.String() + "'/>",
 		From:          fn,
+		FromAddress:   f.Address,
+		IsSMS:         channel == ChannelSMS,
+		Token:         token,
 		// Keep RId for backward compatibility
 		// Consider logging a deprecation warning if used.
 		 RId:           rid,
-	}, nil
+	}
+
+	if channel == ChannelSMS {
+		// SMS bodies can't carry a hidden <img> tracker, so skip it
+		// entirely and hand back a short link instead; a click on it is
+		// the only open/engagement signal a smishing campaign gets.
+		if !persistShortURL {
+			// Validation never resolves this link, so fabricate a
+			// short-URL-shaped stub rather than writing a row for a
+			// template that's only being test-rendered.
+			ptx.ShortURL = baseURL.String() + shortURLPath("validate")
+			return ptx, nil
+		}
+		su, err := CreateShortURL(phishURL.String(), rid)
+		if err != nil {
+			return PhishingTemplateContext{}, fmt.Errorf("failed to create short URL: %w", err)
+		}
+		ptx.ShortURL = baseURL.String() + shortURLPath(su.Token)
+		return ptx, nil
+	}
+
+	// Generate the tracker image tag for email, escaping TrackingURL for
+	// use as an HTML attribute value via simple string concatenation.
+	ptx.Tracker = "<img alt='' style='display: none' src='" + trackingURL.String() + "'/>"
+	return ptx, nil
 }
 
 // Helper function to get current time formatted as HH:MM
@@ -135,34 +209,87 @@ func currentTimeHHMM() string {
 	return time.Now().Format("15:04")
 }
 
+// maxTemplateOutputBytes bounds how much a single template render may
+// write. It's large enough for any legitimate email/landing page body,
+// but stops a template that loops over a huge generated range (e.g.
+// {{range seq 100000000}}) from exhausting memory.
+const maxTemplateOutputBytes = 10 * 1024 * 1024
+
+// templateExecTimeout bounds how long a single template render may run.
+// Campaign templates are attacker-controlled input from the operator's
+// perspective of the sender goroutine, so a template that spins forever
+// (e.g. deeply recursive named templates) must not be able to wedge the
+// goroutine that's sending the rest of the campaign.
+const templateExecTimeout = 5 * time.Second
+
+// limitedBuffer is a bytes.Buffer that refuses writes once it has
+// accumulated more than limit bytes. text/template aborts Execute and
+// surfaces the Write error as soon as this happens, so it doubles as a
+// loop/recursion cap for templates that generate unbounded output.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.Buffer.Len()+len(p) > b.limit {
+		return 0, fmt.Errorf("template output exceeded the %d byte limit", b.limit)
+	}
+	return b.Buffer.Write(p)
+}
+
 // ExecuteTemplate creates a templated string based on the provided
-// template body and data.
+// template body and data. It has no access to a user's saved
+// TemplatePartials; use ExecuteTemplateForUser when text may reference
+// one with {{template "name" .}}.
 func ExecuteTemplate(text string, data interface{}) (string, error) {
-	// Define custom functions
-	funcMap := template.FuncMap{
-		"hora": currentTimeHHMM, // Add the 'hora' function
+	return executeTemplateSet(nil, text, data)
+}
+
+// ExecuteTemplateForUser is like ExecuteTemplate, but first loads uid's
+// saved TemplatePartials into the template set so that text can pull
+// them in with {{template "name" .}} (e.g. a shared {{template
+// "signature" .}}).
+func ExecuteTemplateForUser(text string, data interface{}, uid int64) (string, error) {
+	partials, err := GetTemplatePartials(uid)
+	if err != nil {
+		return "", fmt.Errorf("error loading template partials: %w", err)
 	}
+	return executeTemplateSet(partials, text, data)
+}
 
-	// Create a new template with the function map
-	// Use a unique name for the template, e.g., "gophish_template"
-	 tmpl, err := template.New("gophish_template").Funcs(funcMap).Parse(text)
-	 if err != nil {
-	 	// Return error if template parsing fails
-	 	// Include template name or context if possible for better debugging
-	 	return "", fmt.Errorf("error parsing template: %w", err)
-	 }
+// executeTemplateSet builds a template.Template set out of partials
+// (each registered under {{define "<partial.Name>"}}) plus text as the
+// set's root template, then executes the root with data.
+func executeTemplateSet(partials []TemplatePartial, text string, data interface{}) (string, error) {
+	set := template.New("gophish_template").Funcs(FuncMap())
+	for _, p := range partials {
+		partial, err := set.New(p.Name).Parse(p.Body)
+		if err != nil {
+			return "", fmt.Errorf("error parsing template partial %q: %w", p.Name, err)
+		}
+		set = partial
+	}
+	tmpl, err := set.New("gophish_template").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("error parsing template: %w", err)
+	}
 
-	// Execute the template
-	 var buff bytes.Buffer
-	 err = tmpl.Execute(&buff, data)
-	 if err != nil {
-	 	// Return error if template execution fails
-	 	// This often happens if template uses undefined fields or functions
-	 	return "", fmt.Errorf("error executing template: %w", err)
-	 }
+	buff := &limitedBuffer{limit: maxTemplateOutputBytes}
+	done := make(chan error, 1)
+	go func() {
+		done <- tmpl.Execute(buff, data)
+	}()
 
-	// Return the executed template content
-	 return buff.String(), nil
+	select {
+	case err := <-done:
+		if err != nil {
+			return "", fmt.Errorf("error executing template: %w", err)
+		}
+		return buff.String(), nil
+	case <-time.After(templateExecTimeout):
+		return "", fmt.Errorf("template execution exceeded %s; aborting to avoid wedging the sender", templateExecTimeout)
+	}
 }
 
 // ValidationContext is used for validating templates and pages
@@ -180,8 +307,32 @@ func (vc ValidationContext) getBaseURL() string {
 }
 
 // ValidateTemplate ensures that the provided text in the page or template
-// uses the supported template variables correctly.
+// uses the supported template variables correctly. It has no access to
+// any user's saved TemplatePartials; use ValidateTemplateForUser to
+// validate text that may reference one.
 func ValidateTemplate(text string) error {
+	return validateTemplate(text, nil, ChannelEmail)
+}
+
+// ValidateTemplateForUser is like ValidateTemplate, but also resolves
+// uid's saved TemplatePartials, so a template that does
+// {{template "signature" .}} validates correctly.
+func ValidateTemplateForUser(text string, uid int64) error {
+	partials, err := GetTemplatePartials(uid)
+	if err != nil {
+		return fmt.Errorf("error loading template partials: %w", err)
+	}
+	return validateTemplate(text, partials, ChannelEmail)
+}
+
+// validateTemplate runs text through the same rendering path a real send
+// would use, against a dummy context for channel, to catch execution
+// errors (unknown fields, bad template syntax) before a template is
+// saved. It builds that dummy context with a literal placeholder token
+// and persistShortURL=false, so validating a template never requires a
+// live DB connection -- unlike a real send, nothing here is ever clicked
+// or needs to resolve.
+func validateTemplate(text string, partials []TemplatePartial, channel Channel) error {
 	// Create a dummy validation context
 	 vc := ValidationContext{
 	 	FromAddress: "foo@bar.com",
@@ -198,15 +349,24 @@ func ValidateTemplate(text string) error {
 	 	// Use a fixed RId for validation
 	 	 RId: "validate123",
 	 }
-	// Create the phishing template context using dummy data
-	 ptx, err := NewPhishingTemplateContext(vc, td.BaseRecipient, td.RId)
+	// Create the phishing template context using dummy data. No DB
+	// access happens here: "validate-token" stands in for a real signed
+	// token, and persistShortURL=false skips the ShortURL DB write on
+	// the ChannelSMS path.
+	 ptx, err := newPhishingTemplateContext(vc, td.BaseRecipient, td.RId, channel, "validate-token", false)
 	 if err != nil {
 	 	// If context creation fails, validation fails
 	 	return fmt.Errorf("error creating validation context: %w", err)
 	 }
+	// Dummy original message, so a reply-hijacking template validates too
+	ptx.Original = Original{
+		From:    "Original Sender <sender@example.com>",
+		Subject: "Original Subject",
+		Text:    "Original message body.",
+	}
 	// Attempt to execute the template with the dummy context
 	 // This will parse the template and check for execution errors
-	 _, err = ExecuteTemplate(text, ptx)
+	 _, err = executeTemplateSet(partials, text, ptx)
 	 if err != nil {
 	 	// If execution fails, the template is invalid
 	 	return fmt.Errorf("template validation failed: %w", err)
@@ -0,0 +1,186 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"net/url"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// FuncMap returns the set of functions available to every template and
+// landing page rendered through ExecuteTemplate. It's exported so that
+// callers outside this package (e.g. a future template editor/preview
+// endpoint) can document or introspect the available helpers without
+// duplicating this list.
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		// Legacy helper, kept for templates created before this FuncMap
+		// existed.
+		"hora": currentTimeHHMM,
+
+		// Date/time
+		"now":        time.Now,
+		"date":       tmplDate,
+		"dateInZone": tmplDateInZone,
+		"addDays":    tmplAddDays,
+
+		// String manipulation
+		"upper":        strings.ToUpper,
+		"lower":        strings.ToLower,
+		"title":        strings.Title,
+		"trim":         strings.TrimSpace,
+		"replace":      tmplReplace,
+		"regexReplace": tmplRegexReplace,
+		"split":        strings.Split,
+		"quote":        quote,
+
+		// Conditional/logical
+		"default":  tmplDefault,
+		"coalesce": tmplCoalesce,
+		"ternary":  tmplTernary,
+
+		// URL/encoding helpers
+		"urlquery": url.QueryEscape,
+		"b64enc":   tmplB64enc,
+		"b64dec":   tmplB64dec,
+		"hexenc":   tmplHexenc,
+
+		// Fake-data generators, deterministic per recipient so that
+		// template previews are stable across repeated renders.
+		"fakeCompany": fakeCompany,
+		"fakePhone":   fakePhone,
+		"fakeIP":      fakeIP,
+	}
+}
+
+// tmplDate formats t using layout, a Go reference-time layout string
+// (see the time package for the supported syntax).
+func tmplDate(layout string, t time.Time) string {
+	return t.Format(layout)
+}
+
+// tmplDateInZone formats t using layout after converting it to the named
+// IANA time zone (e.g. "America/New_York"). An unknown zone name falls
+// back to UTC rather than erroring out a template render.
+func tmplDateInZone(layout, zone string, t time.Time) string {
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		loc = time.UTC
+	}
+	return t.In(loc).Format(layout)
+}
+
+// tmplAddDays returns t shifted forward (or backward, for negative n)
+// by n days.
+func tmplAddDays(n int, t time.Time) time.Time {
+	return t.AddDate(0, 0, n)
+}
+
+// tmplReplace replaces every occurrence of old with new in s, mirroring
+// strings.ReplaceAll's argument order shifted to suit template pipelines.
+func tmplReplace(old, new, s string) string {
+	return strings.ReplaceAll(s, old, new)
+}
+
+// tmplRegexReplace replaces every match of pattern in s with repl. An
+// invalid pattern returns s unchanged rather than aborting the render,
+// since a typo in a campaign template shouldn't fail the whole send.
+func tmplRegexReplace(pattern, repl, s string) string {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return s
+	}
+	return re.ReplaceAllString(s, repl)
+}
+
+// tmplDefault returns value unless it is the empty string, in which case
+// it returns def. This mirrors Sprig's "default" helper.
+func tmplDefault(def, value string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
+
+// tmplCoalesce returns the first non-empty string in values, or "" if
+// every value is empty.
+func tmplCoalesce(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// tmplTernary returns yes if cond is true, otherwise no.
+func tmplTernary(yes, no string, cond bool) string {
+	if cond {
+		return yes
+	}
+	return no
+}
+
+// tmplB64enc base64-encodes s using standard encoding.
+func tmplB64enc(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+// tmplB64dec base64-decodes s, returning the empty string if s isn't
+// valid base64 rather than failing the render.
+func tmplB64dec(s string) string {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// tmplHexenc hex-encodes s.
+func tmplHexenc(s string) string {
+	return hex.EncodeToString([]byte(s))
+}
+
+// seedFor derives a stable per-recipient seed from email, so that
+// fakeCompany/fakePhone/fakeIP return the same value every time a given
+// recipient's template is rendered, keeping previews deterministic.
+func seedFor(email string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(email))
+	return h.Sum64()
+}
+
+var fakeCompanyNames = []string{
+	"Initech", "Globex Corporation", "Umbrella Corp", "Soylent Corp",
+	"Hooli", "Acme Co", "Stark Industries", "Wayne Enterprises",
+	"Wonka Industries", "Cyberdyne Systems",
+}
+
+// fakeCompany returns a deterministic, plausible-looking company name
+// for the given recipient email.
+func fakeCompany(email string) string {
+	return fakeCompanyNames[seedFor(email)%uint64(len(fakeCompanyNames))]
+}
+
+// fakePhone returns a deterministic, NANP-formatted fake phone number
+// for the given recipient email.
+func fakePhone(email string) string {
+	seed := seedFor(email)
+	area := 200 + seed%700
+	exchange := 200 + (seed/700)%700
+	line := seed % 10000
+	return fmt.Sprintf("(%03d) %03d-%04d", area, exchange, line)
+}
+
+// fakeIP returns a deterministic IPv4 address in a documentation/testing
+// range (TEST-NET-1, RFC 5737) for the given recipient email, so
+// generated addresses never collide with a real host.
+func fakeIP(email string) string {
+	seed := seedFor(email)
+	return fmt.Sprintf("192.0.2.%d", seed%256)
+}
@@ -0,0 +1,156 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTmplDate(t *testing.T) {
+	ts := time.Date(2026, time.July, 4, 15, 4, 5, 0, time.UTC)
+	got := tmplDate("2006-01-02", ts)
+	if got != "2026-07-04" {
+		t.Fatalf("tmplDate: got %q, want %q", got, "2026-07-04")
+	}
+}
+
+func TestTmplDateInZone(t *testing.T) {
+	ts := time.Date(2026, time.July, 4, 15, 0, 0, 0, time.UTC)
+	got := tmplDateInZone("15:04", "America/New_York", ts)
+	if got != "11:00" {
+		t.Fatalf("tmplDateInZone: got %q, want %q", got, "11:00")
+	}
+
+	// An unknown zone name should fall back to UTC rather than erroring.
+	got = tmplDateInZone("15:04", "Not/AZone", ts)
+	if got != "15:00" {
+		t.Fatalf("tmplDateInZone with unknown zone: got %q, want %q", got, "15:00")
+	}
+}
+
+func TestTmplAddDays(t *testing.T) {
+	ts := time.Date(2026, time.July, 4, 0, 0, 0, 0, time.UTC)
+	got := tmplAddDays(3, ts)
+	want := time.Date(2026, time.July, 7, 0, 0, 0, 0, time.UTC)
+	if !got.Equal(want) {
+		t.Fatalf("tmplAddDays(3, ...): got %v, want %v", got, want)
+	}
+
+	got = tmplAddDays(-1, ts)
+	want = time.Date(2026, time.July, 3, 0, 0, 0, 0, time.UTC)
+	if !got.Equal(want) {
+		t.Fatalf("tmplAddDays(-1, ...): got %v, want %v", got, want)
+	}
+}
+
+func TestTmplReplace(t *testing.T) {
+	got := tmplReplace("foo", "bar", "foo foo baz")
+	if got != "bar bar baz" {
+		t.Fatalf("tmplReplace: got %q", got)
+	}
+}
+
+func TestTmplRegexReplace(t *testing.T) {
+	got := tmplRegexReplace(`\d+`, "#", "room 204b")
+	if got != "room #b" {
+		t.Fatalf("tmplRegexReplace: got %q", got)
+	}
+
+	// An invalid pattern shouldn't abort the render; it returns s as-is.
+	got = tmplRegexReplace("(", "#", "room 204b")
+	if got != "room 204b" {
+		t.Fatalf("tmplRegexReplace with invalid pattern: got %q", got)
+	}
+}
+
+func TestTmplDefault(t *testing.T) {
+	if got := tmplDefault("fallback", ""); got != "fallback" {
+		t.Fatalf("tmplDefault with empty value: got %q", got)
+	}
+	if got := tmplDefault("fallback", "set"); got != "set" {
+		t.Fatalf("tmplDefault with non-empty value: got %q", got)
+	}
+}
+
+func TestTmplCoalesce(t *testing.T) {
+	if got := tmplCoalesce("", "", "third"); got != "third" {
+		t.Fatalf("tmplCoalesce: got %q", got)
+	}
+	if got := tmplCoalesce("", ""); got != "" {
+		t.Fatalf("tmplCoalesce with all empty: got %q", got)
+	}
+}
+
+func TestTmplTernary(t *testing.T) {
+	if got := tmplTernary("yes", "no", true); got != "yes" {
+		t.Fatalf("tmplTernary(true): got %q", got)
+	}
+	if got := tmplTernary("yes", "no", false); got != "no" {
+		t.Fatalf("tmplTernary(false): got %q", got)
+	}
+}
+
+func TestTmplB64encDec(t *testing.T) {
+	enc := tmplB64enc("hello")
+	if enc != "aGVsbG8=" {
+		t.Fatalf("tmplB64enc: got %q", enc)
+	}
+	if got := tmplB64dec(enc); got != "hello" {
+		t.Fatalf("tmplB64dec: got %q", got)
+	}
+	// Invalid base64 should return "" rather than erroring.
+	if got := tmplB64dec("not valid base64!!"); got != "" {
+		t.Fatalf("tmplB64dec with invalid input: got %q, want empty string", got)
+	}
+}
+
+func TestTmplHexenc(t *testing.T) {
+	if got := tmplHexenc("AB"); got != "4142" {
+		t.Fatalf("tmplHexenc: got %q", got)
+	}
+}
+
+func TestFakeHelpersAreDeterministic(t *testing.T) {
+	email := "victim@example.com"
+	if fakeCompany(email) != fakeCompany(email) {
+		t.Fatalf("fakeCompany is not deterministic for the same email")
+	}
+	if fakePhone(email) != fakePhone(email) {
+		t.Fatalf("fakePhone is not deterministic for the same email")
+	}
+	if fakeIP(email) != fakeIP(email) {
+		t.Fatalf("fakeIP is not deterministic for the same email")
+	}
+	if fakeCompany(email) == fakeCompany("other@example.com") &&
+		fakePhone(email) == fakePhone("other@example.com") &&
+		fakeIP(email) == fakeIP("other@example.com") {
+		t.Fatalf("fake helpers returned identical output for two different recipients")
+	}
+}
+
+func TestFuncMapExecutesThroughTemplate(t *testing.T) {
+	text := `{{upper .Name | trim}}-{{"foo" | default "bar"}}`
+	out, err := ExecuteTemplate(text, struct{ Name string }{Name: "  alice  "})
+	if err != nil {
+		t.Fatalf("ExecuteTemplate returned error: %v", err)
+	}
+	if out != "ALICE-foo" {
+		t.Fatalf("ExecuteTemplate: got %q", out)
+	}
+}
+
+func TestLimitedBufferEnforcesOutputCap(t *testing.T) {
+	buf := &limitedBuffer{limit: 8}
+	if _, err := buf.Write([]byte("1234")); err != nil {
+		t.Fatalf("write under the limit returned an unexpected error: %v", err)
+	}
+	if _, err := buf.Write([]byte("5678")); err != nil {
+		t.Fatalf("write reaching exactly the limit returned an unexpected error: %v", err)
+	}
+	if _, err := buf.Write([]byte("9")); err == nil {
+		t.Fatalf("expected an error once a write pushes past the limit")
+	}
+	if !strings.Contains(buf.String(), "12345678") {
+		t.Fatalf("limitedBuffer lost data accepted before the limit was hit: %q", buf.String())
+	}
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	log "github.com/gophish/gophish/logger"
+)
+
+// TemplatePartial models a named, reusable block of template text (e.g.
+// a signature) that a Template's Subject/HTML/Text can pull in with
+// {{template "name" .}}. Partials are owned per-user, the same way
+// Templates are, so one operator's partials can't leak into another's
+// campaigns.
+type TemplatePartial struct {
+	Id           int64     `json:"id" gorm:"column:id; primary_key:yes"`
+	UserId       int64     `json:"-" gorm:"column:user_id"`
+	Name         string    `json:"name"`
+	Body         string    `json:"body"`
+	ModifiedDate time.Time `json:"modified_date"`
+}
+
+// ErrTemplatePartialNameNotSpecified is thrown when a partial is saved
+// without a name.
+var ErrTemplatePartialNameNotSpecified = errors.New("Template partial name not specified")
+
+// ErrTemplatePartialMissingBody is thrown when a partial is saved
+// without a body.
+var ErrTemplatePartialMissingBody = errors.New("Template partial body not specified")
+
+// Validate checks the given partial to make sure values are appropriate
+// and complete.
+func (p *TemplatePartial) Validate() error {
+	switch {
+	case p.Name == "":
+		return ErrTemplatePartialNameNotSpecified
+	case p.Body == "":
+		return ErrTemplatePartialMissingBody
+	}
+	return ValidateTemplate(p.Body)
+}
+
+// GetTemplatePartials returns the partials owned by the given user.
+func GetTemplatePartials(uid int64) ([]TemplatePartial, error) {
+	ps := []TemplatePartial{}
+	err := db.Where("user_id=?", uid).Find(&ps).Error
+	if err != nil {
+		log.Error(err)
+	}
+	return ps, err
+}
+
+// GetTemplatePartial returns the partial, if it exists, specified by the
+// given id and user_id.
+func GetTemplatePartial(id int64, uid int64) (TemplatePartial, error) {
+	p := TemplatePartial{}
+	err := db.Where("user_id=? and id=?", uid, id).Find(&p).Error
+	if err != nil {
+		log.Error(err)
+	}
+	return p, err
+}
+
+// PostTemplatePartial creates a new partial in the database.
+func PostTemplatePartial(p *TemplatePartial) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	err := db.Save(p).Error
+	if err != nil {
+		log.Error(err)
+	}
+	return err
+}
+
+// PutTemplatePartial edits an existing partial in the database.
+func PutTemplatePartial(p *TemplatePartial) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	err := db.Save(p).Error
+	if err != nil {
+		log.Error(err)
+	}
+	return err
+}
+
+// DeleteTemplatePartial deletes an existing partial in the database.
+func DeleteTemplatePartial(id int64, uid int64) error {
+	err := db.Where("user_id=?", uid).Delete(&TemplatePartial{Id: id}).Error
+	if err != nil {
+		log.Error(err)
+	}
+	return err
+}